@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "image_registry_operator_reconcile_duration_seconds",
+		Help:    "Histogram of how long a controller's reconcile took, by outcome.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"controller", "result"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "image_registry_operator_reconcile_errors_total",
+		Help: "Counts reconcile errors, by controller.",
+	}, []string{"controller"})
+
+	reconcileRequeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "image_registry_operator_reconcile_requeues_total",
+		Help: "Counts reconciles that asked to be requeued, by controller.",
+	}, []string{"controller"})
+)
+
+func init() {
+	prometheus.MustRegister(reconcileDuration, reconcileErrors, reconcileRequeues)
+
+	// Registered explicitly (rather than relying on the defaults client_golang
+	// versions have carried in the past) so the scrape endpoint always looks
+	// like a first-class target with process/Go runtime metrics, regardless
+	// of vendored client_golang version.
+	registerCollector(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	registerCollector(collectors.NewGoCollector())
+}
+
+func registerCollector(c prometheus.Collector) {
+	if err := prometheus.Register(c); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			panic(err)
+		}
+	}
+}
+
+// ObserveReconcile records the duration and outcome of a single reconcile
+// for controller. result should be a short, low-cardinality label such as
+// "success", "error" or "requeue". Controllers are expected to call this
+// with defer and time.Since at the top of their Reconcile method, e.g.:
+//
+//	start := time.Now()
+//	defer func() { metrics.ObserveReconcile("storage", result, time.Since(start)) }()
+//
+// NOTE: this tree does not contain the operator's controllers (pkg/metrics
+// is the only package present), so there is nothing here to wire this -
+// and ReconcileError/ReconcileRequeue below - into yet. Whichever PR adds
+// controllers back into this tree needs to call these from each
+// controller's Reconcile.
+func ObserveReconcile(controller, result string, duration time.Duration) {
+	reconcileDuration.WithLabelValues(controller, result).Observe(duration.Seconds())
+}
+
+// ReconcileError increments the reconcile error counter for controller.
+func ReconcileError(controller string) {
+	reconcileErrors.WithLabelValues(controller).Inc()
+}
+
+// ReconcileRequeue increments the reconcile requeue counter for controller.
+func ReconcileRequeue(controller string) {
+	reconcileRequeues.WithLabelValues(controller).Inc()
+}
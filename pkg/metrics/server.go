@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ErrMissingMetricsTLS is returned by RunServerWithConfig when Scheme is
+// "https" but the TLS key and/or certificate path is empty or unreadable,
+// so that the caller learns about a misconfiguration immediately instead
+// of the server failing silently on the first scrape's handshake.
+var ErrMissingMetricsTLS = errors.New("metrics: https scheme requires a readable TLS key and certificate")
+
+// Config holds the settings RunServerWithConfig needs to start the metrics
+// server.
+type Config struct {
+	// Port is the TCP port the metrics server listens on.
+	Port int
+
+	// Scheme is either "http" or "https". Defaults to "https".
+	Scheme string
+
+	// TLSKeyFile, TLSCertFile and TLSClientCAFile are paths to PEM files.
+	// They are only consulted when Scheme is "https"; TLSClientCAFile is
+	// optional even then.
+	TLSKeyFile      string
+	TLSCertFile     string
+	TLSClientCAFile string
+
+	// TLSReloadInterval is how often the above files are polled for
+	// changes. Defaults to defaultCertReloadInterval when zero.
+	TLSReloadInterval time.Duration
+}
+
+var (
+	// tlsKey and tlsCRT are the paths to the key/certificate pair the
+	// metrics server presents to scrapers.
+	tlsKey string
+	tlsCRT string
+
+	// tlsClientCA is the path to a PEM CA bundle used to verify client
+	// certificates presented by scrapers. When empty, the server accepts
+	// scrapes from any client.
+	tlsClientCA string
+)
+
+var (
+	storageReconfigured = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "image_registry_operator_storage_reconfigured_total",
+		Help: "Counts the number of times the operator has reconfigured the image registry's storage backend.",
+	})
+
+	imagePrunerInstallStatus = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "image_registry_operator_image_pruner_install_status",
+		Help: "Reports the install status of the image pruner: 0 - not installed, 1 - installed but suspended, 2 - installed and enabled.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(storageReconfigured)
+	prometheus.MustRegister(imagePrunerInstallStatus)
+}
+
+// StorageReconfigured increments the counter tracking how many times the
+// operator has reconfigured the image registry's storage backend.
+func StorageReconfigured() {
+	storageReconfigured.Inc()
+}
+
+// ImagePrunerInstallStatus records the current install state of the image
+// pruner: not installed, installed but suspended, or installed and enabled.
+func ImagePrunerInstallStatus(installed, enabled bool) {
+	switch {
+	case !installed:
+		imagePrunerInstallStatus.Set(0)
+	case !enabled:
+		imagePrunerInstallStatus.Set(1)
+	default:
+		imagePrunerInstallStatus.Set(2)
+	}
+}
+
+// RunServer starts the metrics HTTP server on the given port using the
+// package's tlsKey/tlsCRT/tlsClientCA paths, logging and exiting the
+// process on any error. It is a thin wrapper around RunServerWithConfig
+// kept for existing callers that don't care to handle a startup error
+// themselves.
+func RunServer(port int) {
+	err := RunServerWithConfig(Config{
+		Port:            port,
+		Scheme:          "https",
+		TLSKeyFile:      tlsKey,
+		TLSCertFile:     tlsCRT,
+		TLSClientCAFile: tlsClientCA,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// RunServerWithConfig starts the metrics server according to cfg, serving
+// the /metrics endpoint and blocking until the server stops. When
+// cfg.Scheme is "https" (the default), the serving certificate, key and
+// (when cfg.TLSClientCAFile is set) client CA bundle are loaded from disk
+// and watched for changes, so that a certificate rotation is picked up
+// without restarting the server; a client certificate signed by
+// cfg.TLSClientCAFile is then required and verified on every scrape. It
+// returns ErrMissingMetricsTLS immediately, before binding a listener, if
+// cfg.Scheme is "https" and the key or certificate isn't a readable file.
+func RunServerWithConfig(cfg Config) error {
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Port),
+		Handler: mux,
+	}
+
+	if scheme != "https" {
+		return server.ListenAndServe()
+	}
+
+	if err := checkReadableFile(cfg.TLSKeyFile); err != nil {
+		return fmt.Errorf("%w: key %q: %v", ErrMissingMetricsTLS, cfg.TLSKeyFile, err)
+	}
+	if err := checkReadableFile(cfg.TLSCertFile); err != nil {
+		return fmt.Errorf("%w: certificate %q: %v", ErrMissingMetricsTLS, cfg.TLSCertFile, err)
+	}
+
+	reloader, err := newCertReloader(cfg.TLSKeyFile, cfg.TLSCertFile, cfg.TLSClientCAFile, cfg.TLSReloadInterval)
+	if err != nil {
+		return err
+	}
+	go reloader.watch(nil)
+
+	server.TLSConfig = &tls.Config{
+		GetCertificate:     reloader.GetCertificate,
+		GetConfigForClient: reloader.GetConfigForClient,
+	}
+	reloader.baseConfig = server.TLSConfig
+
+	return server.ListenAndServeTLS("", "")
+}
+
+// checkReadableFile returns an error if path is empty or cannot be opened
+// for reading.
+func checkReadableFile(path string) error {
+	if path == "" {
+		return errors.New("no path configured")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
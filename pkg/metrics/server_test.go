@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"io"
 	"log"
 	"math/big"
@@ -136,7 +137,7 @@ func TestStorageReconfigured(t *testing.T) {
 				t.Fatalf("error requesting metrics server: %v", err)
 			}
 
-			metrics := findMetricsByCounter(resp.Body, metricName)
+			metrics := findMetricsByName(resp.Body, metricName)
 			if len(metrics) == 0 {
 				t.Fatal("unable to locate metric", metricName)
 			}
@@ -181,7 +182,7 @@ func TestImagePrunerInstallStatus(t *testing.T) {
 				t.Fatalf("error requesting metrics server: %v", err)
 			}
 
-			metrics := findMetricsByCounter(resp.Body, metricName)
+			metrics := findMetricsByName(resp.Body, metricName)
 			if len(metrics) == 0 {
 				t.Fatal("unable to locate metric", metricName)
 			}
@@ -203,14 +204,351 @@ func TestImagePrunerInstallStatus(t *testing.T) {
 
 }
 
-func findMetricsByCounter(buf io.ReadCloser, name string) []*io_prometheus_client.Metric {
+func TestRunServerWithConfigMissingTLS(t *testing.T) {
+	validKey, validCRT, err := generateTempCertificates()
+	if err != nil {
+		t.Fatalf("error generating certificates: %v", err)
+	}
+	defer os.Remove(validKey)
+	defer os.Remove(validCRT)
+
+	testCases := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "empty key",
+			cfg:  Config{Port: 5003, Scheme: "https", TLSCertFile: validCRT},
+		},
+		{
+			name: "empty cert",
+			cfg:  Config{Port: 5003, Scheme: "https", TLSKeyFile: validKey},
+		},
+		{
+			name: "unreadable cert",
+			cfg:  Config{Port: 5003, Scheme: "https", TLSKeyFile: validKey, TLSCertFile: "/does/not/exist.crt"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := RunServerWithConfig(tc.cfg)
+			if !errors.Is(err, ErrMissingMetricsTLS) {
+				t.Errorf("expected ErrMissingMetricsTLS, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunServerWithConfigValidTLS(t *testing.T) {
+	validKey, validCRT, err := generateTempCertificates()
+	if err != nil {
+		t.Fatalf("error generating certificates: %v", err)
+	}
+	defer os.Remove(validKey)
+	defer os.Remove(validCRT)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunServerWithConfig(Config{Port: 5003, Scheme: "https", TLSKeyFile: validKey, TLSCertFile: validCRT})
+	}()
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("expected the server to start and keep running, got error: %v", err)
+	case <-time.After(time.Second):
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	resp, err := client.Get("https://localhost:5003/metrics")
+	if err != nil {
+		t.Fatalf("error requesting metrics server: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, received %d instead.", resp.StatusCode)
+	}
+}
+
+func TestCertificateHotReload(t *testing.T) {
+	const reloadInterval = 200 * time.Millisecond
+
+	keyPath, crtPath, err := generateTempCertificates()
+	if err != nil {
+		t.Fatalf("error generating certificates: %v", err)
+	}
+	defer os.Remove(keyPath)
+	defer os.Remove(crtPath)
+
+	go RunServerWithConfig(Config{Port: 5002, Scheme: "https", TLSKeyFile: keyPath, TLSCertFile: crtPath, TLSReloadInterval: reloadInterval})
+
+	// give the server time to come up before hitting it.
+	time.Sleep(time.Second)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+	}
+
+	resp, err := client.Get("https://localhost:5002/metrics")
+	if err != nil {
+		t.Fatalf("error requesting metrics server: %v", err)
+	}
+	resp.Body.Close()
+	firstLeaf := resp.TLS.PeerCertificates[0]
+
+	newKeyPath, newCRTPath, err := generateTempCertificates()
+	if err != nil {
+		t.Fatalf("error generating replacement certificates: %v", err)
+	}
+	defer os.Remove(newKeyPath)
+	defer os.Remove(newCRTPath)
+
+	for _, rename := range []struct{ from, to string }{
+		{newKeyPath, keyPath},
+		{newCRTPath, crtPath},
+	} {
+		data, err := os.ReadFile(rename.from)
+		if err != nil {
+			t.Fatalf("error reading replacement file: %v", err)
+		}
+		if err := os.WriteFile(rename.to, data, 0o600); err != nil {
+			t.Fatalf("error writing replacement file: %v", err)
+		}
+	}
+
+	// give the reloader time to notice the change on disk.
+	time.Sleep(5 * reloadInterval)
+
+	resp, err = client.Get("https://localhost:5002/metrics")
+	if err != nil {
+		t.Fatalf("error requesting metrics server: %v", err)
+	}
+	resp.Body.Close()
+	secondLeaf := resp.TLS.PeerCertificates[0]
+
+	if firstLeaf.Equal(secondLeaf) {
+		t.Error("expected the server to present a new leaf certificate after rotation, got the same one")
+	}
+}
+
+func TestClientCertificateAuthentication(t *testing.T) {
+	caCRT, caKey, err := generateTempCA()
+	if err != nil {
+		t.Fatalf("error generating CA: %v", err)
+	}
+	defer os.Remove(caCRT)
+	defer os.Remove(caKey)
+
+	clientCert, err := generateTempClientCertificate(caCRT, caKey)
+	if err != nil {
+		t.Fatalf("error generating client certificate: %v", err)
+	}
+
+	tlsClientCA = caCRT
+	defer func() { tlsClientCA = "" }()
+
+	go RunServer(5001)
+	time.Sleep(time.Second)
+
+	t.Run("without client certificate", func(t *testing.T) {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			},
+		}
+
+		_, err := client.Get("https://localhost:5001/metrics")
+		if err == nil {
+			t.Fatal("expected the scrape to be rejected for missing a client certificate, got no error")
+		}
+	})
+
+	t.Run("with a valid client certificate", func(t *testing.T) {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+					Certificates:       []tls.Certificate{clientCert},
+				},
+			},
+		}
+
+		resp, err := client.Get("https://localhost:5001/metrics")
+		if err != nil {
+			t.Fatalf("error requesting metrics server: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected 200, received %d instead.", resp.StatusCode)
+		}
+	})
+}
+
+// generateTempCA generates a self-signed CA certificate and returns the
+// paths to its PEM-encoded certificate and key.
+func generateTempCA() (string, string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		return "", "", err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, key.Public(), key)
+	if err != nil {
+		return "", "", err
+	}
+
+	crt, err := os.CreateTemp("", "testca-crt-")
+	if err != nil {
+		return "", "", err
+	}
+	defer crt.Close()
+	if err := pem.Encode(crt, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes}); err != nil {
+		return "", "", err
+	}
+
+	keyFile, err := os.CreateTemp("", "testca-key-")
+	if err != nil {
+		return "", "", err
+	}
+	defer keyFile.Close()
+	if err := pem.Encode(keyFile, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}); err != nil {
+		return "", "", err
+	}
+
+	return crt.Name(), keyFile.Name(), nil
+}
+
+// generateTempClientCertificate generates a client certificate signed by the
+// CA at caCRTPath/caKeyPath and returns it ready to use in a tls.Config.
+func generateTempClientCertificate(caCRTPath, caKeyPath string) (tls.Certificate, error) {
+	caCRTPEM, err := os.ReadFile(caCRTPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	caKeyPEM, err := os.ReadFile(caKeyPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	caCRTBlock, _ := pem.Decode(caCRTPEM)
+	caCRT, err := x509.ParseCertificate(caCRTBlock.Bytes)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	caKeyBlock, _ := pem.Decode(caKeyPEM)
+	caKey, err := x509.ParsePKCS1PrivateKey(caKeyBlock.Bytes)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, caCRT, key.Public(), caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+func TestObserveReconcile(t *testing.T) {
+	metricName := "image_registry_operator_reconcile_duration_seconds"
+
+	ObserveReconcile("test-controller", "success", 10*time.Millisecond)
+	ObserveReconcile("test-controller", "success", 20*time.Millisecond)
+
+	resp, err := http.Get("https://localhost:5000/metrics")
+	if err != nil {
+		t.Fatalf("error requesting metrics server: %v", err)
+	}
+
+	series := findSeries(findMetricsByName(resp.Body, metricName), "controller", "test-controller")
+	if series == nil {
+		t.Fatalf("unable to locate histogram series for metric %s", metricName)
+	}
+
+	if got := series.Histogram.GetSampleCount(); got < 2 {
+		t.Errorf("expected at least 2 samples, got %d", got)
+	}
+}
+
+func TestReconcileErrorAndRequeueCounters(t *testing.T) {
+	ReconcileError("test-controller")
+	ReconcileRequeue("test-controller")
+	ReconcileRequeue("test-controller")
+
+	resp, err := http.Get("https://localhost:5000/metrics")
+	if err != nil {
+		t.Fatalf("error requesting metrics server: %v", err)
+	}
+	families := scrapeAll(resp.Body)
+
+	if m := findSeries(families["image_registry_operator_reconcile_errors_total"], "controller", "test-controller"); m == nil || m.Counter.GetValue() != 1 {
+		t.Errorf("expected reconcile error counter to be 1 for test-controller, got %v", m)
+	}
+
+	if m := findSeries(families["image_registry_operator_reconcile_requeues_total"], "controller", "test-controller"); m == nil || m.Counter.GetValue() != 2 {
+		t.Errorf("expected reconcile requeue counter to be 2 for test-controller, got %v", m)
+	}
+}
+
+// findSeries returns the metric within metrics whose label labelName equals
+// labelValue, or nil if none matches.
+func findSeries(metrics []*io_prometheus_client.Metric, labelName, labelValue string) *io_prometheus_client.Metric {
+	for _, m := range metrics {
+		for _, label := range m.Label {
+			if label.GetName() == labelName && label.GetValue() == labelValue {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func findMetricsByName(buf io.ReadCloser, name string) []*io_prometheus_client.Metric {
+	return scrapeAll(buf)[name]
+}
+
+// scrapeAll decodes every metric family in a /metrics response, keyed by
+// name, so a single scrape can be queried for more than one metric.
+func scrapeAll(buf io.ReadCloser) map[string][]*io_prometheus_client.Metric {
 	defer buf.Close()
+	families := map[string][]*io_prometheus_client.Metric{}
 	mf := io_prometheus_client.MetricFamily{}
 	decoder := expfmt.NewDecoder(buf, "text/plain")
 	for err := decoder.Decode(&mf); err == nil; err = decoder.Decode(&mf) {
-		if *mf.Name == name {
-			return mf.Metric
-		}
+		families[mf.GetName()] = mf.Metric
+		mf = io_prometheus_client.MetricFamily{}
 	}
-	return nil
+	return families
 }
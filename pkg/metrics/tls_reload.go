@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// defaultCertReloadInterval is how often a certReloader polls its watched
+// files for changes when no interval is requested.
+const defaultCertReloadInterval = 30 * time.Second
+
+// certReloader watches a TLS key/certificate pair and, optionally, a client
+// CA bundle on disk and atomically swaps the in-memory copies whenever
+// their contents change. This lets the metrics server pick up a rotated
+// serving certificate (e.g. from the service-CA controller) without
+// needing a pod restart.
+type certReloader struct {
+	keyPath, crtPath, caPath string
+
+	// reloadInterval is how often watch polls the above paths. Each
+	// certReloader gets its own copy (rather than sharing a package var)
+	// so that one server's watch loop never races with another's, e.g.
+	// a test shortening the interval for a server it just started.
+	reloadInterval time.Duration
+
+	// baseConfig is the tls.Config the server was constructed with (it
+	// carries GetCertificate). GetConfigForClient clones it rather than
+	// returning a bare tls.Config, since the TLS stack replaces the whole
+	// connection config with whatever GetConfigForClient returns instead
+	// of merging it in.
+	baseConfig *tls.Config
+
+	cert    atomic.Pointer[tls.Certificate]
+	certSum atomic.Pointer[[sha256.Size]byte]
+
+	caPool atomic.Pointer[x509.CertPool]
+	caSum  atomic.Pointer[[sha256.Size]byte]
+}
+
+// newCertReloader creates a certReloader and performs the initial load of
+// the key/certificate pair (and, if caPath is non-empty, the client CA
+// bundle), returning an error if either cannot be read. A reloadInterval of
+// zero selects defaultCertReloadInterval.
+func newCertReloader(keyPath, crtPath, caPath string, reloadInterval time.Duration) (*certReloader, error) {
+	if reloadInterval <= 0 {
+		reloadInterval = defaultCertReloadInterval
+	}
+
+	r := &certReloader{keyPath: keyPath, crtPath: crtPath, caPath: caPath, reloadInterval: reloadInterval}
+	if err := r.reloadCertificate(); err != nil {
+		return nil, err
+	}
+	if err := r.reloadClientCA(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) reloadCertificate() error {
+	keyPEM, err := os.ReadFile(r.keyPath)
+	if err != nil {
+		return fmt.Errorf("unable to read TLS key %q: %v", r.keyPath, err)
+	}
+	crtPEM, err := os.ReadFile(r.crtPath)
+	if err != nil {
+		return fmt.Errorf("unable to read TLS certificate %q: %v", r.crtPath, err)
+	}
+
+	sum := sha256.Sum256(append(append([]byte{}, crtPEM...), keyPEM...))
+	if prev := r.certSum.Load(); prev != nil && bytes.Equal(prev[:], sum[:]) {
+		return nil
+	}
+
+	cert, err := tls.X509KeyPair(crtPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("unable to parse TLS key pair (%q, %q): %v", r.crtPath, r.keyPath, err)
+	}
+
+	r.cert.Store(&cert)
+	r.certSum.Store(&sum)
+	return nil
+}
+
+func (r *certReloader) reloadClientCA() error {
+	if r.caPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.caPath)
+	if err != nil {
+		return fmt.Errorf("unable to read client CA bundle %q: %v", r.caPath, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if prev := r.caSum.Load(); prev != nil && bytes.Equal(prev[:], sum[:]) {
+		return nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("unable to parse any certificates from client CA bundle %q", r.caPath)
+	}
+
+	r.caPool.Store(pool)
+	r.caSum.Store(&sum)
+	return nil
+}
+
+// watch polls the watched files every r.reloadInterval and reloads them on
+// change, until stopCh is closed. A failed reload is logged and the
+// previously loaded certificate/CA pool keeps serving.
+func (r *certReloader) watch(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(r.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := r.reloadCertificate(); err != nil {
+				log.Printf("metrics: unable to reload TLS certificate: %v", err)
+			}
+			if err := r.reloadClientCA(); err != nil {
+				log.Printf("metrics: unable to reload client CA bundle: %v", err)
+			}
+		}
+	}
+}
+
+// GetCertificate is used as tls.Config.GetCertificate so that every new
+// handshake picks up the most recently loaded certificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// GetConfigForClient is used as tls.Config.GetConfigForClient so that
+// client-certificate verification reflects the most recently loaded CA
+// bundle. It returns nil when no client CA is configured, which tells the
+// TLS stack to keep using the base config unmodified.
+//
+// The TLS handshake replaces the whole connection config with whatever this
+// returns rather than merging it into the base config, so the returned
+// config must be a clone of baseConfig (carrying GetCertificate) with only
+// ClientCAs/ClientAuth overridden - not a bare tls.Config - or the server
+// would stop presenting a serving certificate entirely once a client CA is
+// configured.
+func (r *certReloader) GetConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	pool := r.caPool.Load()
+	if pool == nil {
+		return nil, nil
+	}
+
+	cfg := r.baseConfig.Clone()
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return cfg, nil
+}